@@ -1,14 +1,18 @@
 package main
+
 import (
+	"archive/tar"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
@@ -17,23 +21,168 @@ import (
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
+	"github.com/robfig/cron/v3"
 )
 
-
 type Job struct {
-	ID             string `json:"id"`
-	Code           string `json:"code"`
+	ID             string       `json:"id"`
+	Language       string       `json:"language"`
+	Code           string       `json:"code"`
+	ExpectedOutput string       `json:"expected_output"`
+	ActualOutput   string       `json:"actual_output"`
+	TestCases      []TestCase   `json:"test_cases,omitempty"`
+	TestResults    []CaseResult `json:"test_results,omitempty"`
+	Verdict        string       `json:"verdict"`
+	AiDiagnosis    string       `json:"ai_diagnosis"`
+	Status         string       `json:"status"`
+	Attempts       int          `json:"attempts"`
+	CreatedAt      int64        `json:"created_at"`
+}
+
+// TestCase is one graded input/expected-output pair submitted alongside a
+// job. Omitted TimeLimitMs falls back to defaultTimeLimitMs; omitted
+// MemoryMB falls back to the runtime's own MemoryLimit() rather than a
+// flat default, since runtimes like java need more headroom than python.
+type TestCase struct {
+	Stdin          string `json:"stdin"`
 	ExpectedOutput string `json:"expected_output"`
-	ActualOutput   string `json:"actual_output"`
-	Verdict        string `json:"verdict"`     
-	AiDiagnosis    string `json:"ai_diagnosis"` 
-	Status         string `json:"status"`
-	CreatedAt      int64  `json:"created_at"`
+	TimeLimitMs    int    `json:"time_limit_ms"`
+	MemoryMB       int    `json:"memory_mb"`
+}
+
+// CaseResult is the graded outcome of a single TestCase.
+type CaseResult struct {
+	Verdict    string `json:"verdict"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr,omitempty"`
+	WallTimeMs int64  `json:"wall_time_ms"`
+	PeakRSSKB  int64  `json:"peak_rss_kb"`
+}
+
+const defaultTimeLimitMs = 5000
+
+// Runtime is implemented by every language driver so executeCode can treat
+// interpreted and compiled languages the same way.
+type Runtime interface {
+	// Image is the container image the job (and, for compiled runtimes,
+	// the build step) runs in.
+	Image() string
+	// Cmd is the command run inside the container once srcPath is in place.
+	Cmd(srcPath string) []string
+	// Ext is the file extension used for the submitted source file.
+	Ext() string
+	// Compile runs a build step for compiled languages. Interpreted
+	// runtimes are a no-op and return the source's in-container path,
+	// /app/<fileName>.
+	Compile(ctx context.Context, cli *client.Client, srcDir, fileName string) (string, error)
+	// MemoryLimit is the default container memory limit in bytes for this runtime.
+	MemoryLimit() int64
+	// Compiled reports whether Compile does real work. The warm sandbox
+	// pool reuses a single long-lived container via ContainerExec, which
+	// has nowhere to run Compile's separate build container, so it only
+	// ever pools runtimes where this is false.
+	Compiled() bool
+}
+
+// CompileError wraps a failed build-container run so callers can surface
+// the compiler's own diagnostic as a graded "Compilation Error" verdict
+// instead of treating it like an infra failure that deserves a retry.
+type CompileError struct {
+	Output string
+}
+
+func (e *CompileError) Error() string {
+	return "compilation failed:\n" + e.Output
+}
+
+var runtimeRegistry = map[string]Runtime{}
+
+// registerRuntime wires a language driver into the registry; the built-in
+// drivers are registered from init() below, mirroring how the moby engine
+// registers graphdrivers/network backends.
+func registerRuntime(name string, rt Runtime) {
+	runtimeRegistry[name] = rt
+}
+
+func init() {
+	registerRuntime("python", pythonRuntime{})
+	registerRuntime("node", nodeRuntime{})
+	registerRuntime("go", goRuntime{})
+	registerRuntime("cpp", cppRuntime{})
+	registerRuntime("java", javaRuntime{})
+}
+
+type pythonRuntime struct{}
+
+func (pythonRuntime) Image() string               { return "python:alpine" }
+func (pythonRuntime) Cmd(srcPath string) []string { return []string{"python", "-u", srcPath} }
+func (pythonRuntime) Ext() string                 { return ".py" }
+func (pythonRuntime) MemoryLimit() int64          { return 128 * 1024 * 1024 }
+func (pythonRuntime) Compiled() bool              { return false }
+func (pythonRuntime) Compile(ctx context.Context, cli *client.Client, srcDir, fileName string) (string, error) {
+	return filepath.Join("/app", fileName), nil
+}
+
+type nodeRuntime struct{}
+
+func (nodeRuntime) Image() string               { return "node:alpine" }
+func (nodeRuntime) Cmd(srcPath string) []string { return []string{"node", srcPath} }
+func (nodeRuntime) Ext() string                 { return ".js" }
+func (nodeRuntime) MemoryLimit() int64          { return 128 * 1024 * 1024 }
+func (nodeRuntime) Compiled() bool              { return false }
+func (nodeRuntime) Compile(ctx context.Context, cli *client.Client, srcDir, fileName string) (string, error) {
+	return filepath.Join("/app", fileName), nil
+}
+
+type goRuntime struct{}
+
+func (goRuntime) Image() string               { return "golang:alpine" }
+func (goRuntime) Cmd(binPath string) []string { return []string{binPath} }
+func (goRuntime) Ext() string                 { return ".go" }
+func (goRuntime) MemoryLimit() int64          { return 256 * 1024 * 1024 }
+func (goRuntime) Compiled() bool              { return true }
+func (r goRuntime) Compile(ctx context.Context, cli *client.Client, srcDir, fileName string) (string, error) {
+	return buildInContainer(ctx, cli, r.Image(), srcDir,
+		[]string{"go", "build", "-o", "/app/bin", "/app/" + fileName}, r.MemoryLimit())
 }
 
+type cppRuntime struct{}
+
+func (cppRuntime) Image() string               { return "gcc:latest" }
+func (cppRuntime) Cmd(binPath string) []string { return []string{binPath} }
+func (cppRuntime) Ext() string                 { return ".cpp" }
+func (cppRuntime) MemoryLimit() int64          { return 256 * 1024 * 1024 }
+func (cppRuntime) Compiled() bool              { return true }
+func (r cppRuntime) Compile(ctx context.Context, cli *client.Client, srcDir, fileName string) (string, error) {
+	return buildInContainer(ctx, cli, r.Image(), srcDir,
+		[]string{"g++", "-O2", "-o", "/app/bin", "/app/" + fileName}, r.MemoryLimit())
+}
+
+type javaRuntime struct{}
+
+func (javaRuntime) Image() string { return "openjdk:alpine" }
+func (javaRuntime) Cmd(className string) []string {
+	return []string{"java", "-cp", "/app", className}
+}
+func (javaRuntime) Ext() string        { return ".java" }
+func (javaRuntime) MemoryLimit() int64 { return 256 * 1024 * 1024 }
+func (javaRuntime) Compiled() bool     { return true }
+
+// Compile javac's the submission and returns the class name to run, not
+// the source path — javac's build step is pointless if Cmd just hands the
+// .java file to `java`'s single-file source-launcher instead of the
+// compiled .class it just produced.
+func (r javaRuntime) Compile(ctx context.Context, cli *client.Client, srcDir, fileName string) (string, error) {
+	if _, err := buildInContainer(ctx, cli, r.Image(), srcDir,
+		[]string{"javac", "-d", "/app", "/app/" + fileName}, r.MemoryLimit()); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(fileName, filepath.Ext(fileName)), nil
+}
 
 var jobsProcessed = prometheus.NewCounter(
 	prometheus.CounterOpts{
@@ -47,19 +196,411 @@ var aiCalls = prometheus.NewCounter(
 		Help: "Total number of times Nexus AI was triggered",
 	},
 )
+var queueDepth = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "orbit_queue_depth",
+		Help: "Number of jobs waiting in the main queue",
+	},
+)
+var inFlightJobs = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "orbit_in_flight_jobs",
+		Help: "Number of jobs currently claimed by a worker",
+	},
+)
+var deadLetterJobs = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "orbit_dead_letter_jobs",
+		Help: "Number of jobs moved to the dead-letter queue",
+	},
+)
+
+var execLatency = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Name:    "orbit_exec_latency_seconds",
+		Help:    "End-to-end latency of running a submission in a container",
+		Buckets: prometheus.DefBuckets,
+	},
+)
+var poolWaitSeconds = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Name:    "orbit_pool_wait_seconds",
+		Help:    "Time spent acquiring a sandbox from the warm pool, including starting a new one when the pool is empty",
+		Buckets: prometheus.DefBuckets,
+	},
+)
 
 func init() {
 	prometheus.MustRegister(jobsProcessed)
 	prometheus.MustRegister(aiCalls)
+	prometheus.MustRegister(queueDepth)
+	prometheus.MustRegister(inFlightJobs)
+	prometheus.MustRegister(deadLetterJobs)
+	prometheus.MustRegister(execLatency)
+	prometheus.MustRegister(poolWaitSeconds)
+}
+
+// poolSizePerLanguage is the warm-sandbox knob: how many idle containers of
+// each language to keep pre-started. Languages absent from this map always
+// take the cold ContainerCreate+Start+Remove path.
+var poolSizePerLanguage = map[string]int{
+	"python": 4,
+}
+
+// sandboxPool holds pre-started "sleep infinity" containers per language so
+// a submission can run via ContainerExec instead of paying Docker's
+// create/start/remove overhead on every job.
+type sandboxPool struct {
+	mu   sync.Mutex
+	idle map[string]chan string
+}
+
+var pool = &sandboxPool{idle: make(map[string]chan string)}
+
+func (p *sandboxPool) configured(language string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.idle[language]
+	return ok
+}
+
+// acquire takes an idle sandbox if one is available, or cold-starts a new
+// one if the pool is momentarily empty so a burst of jobs doesn't block.
+func (p *sandboxPool) acquire(ctx context.Context, cli *client.Client, language string, rt Runtime) (string, error) {
+	p.mu.Lock()
+	ch := p.idle[language]
+	p.mu.Unlock()
+	if ch == nil {
+		return "", fmt.Errorf("no warm pool configured for %s", language)
+	}
+	select {
+	case id := <-ch:
+		return id, nil
+	default:
+		return createWarmSandbox(ctx, cli, rt)
+	}
+}
+
+// release returns a sandbox to its language's idle channel, or tears it
+// down if the pool is already full.
+func (p *sandboxPool) release(ctx context.Context, cli *client.Client, language, containerID string) {
+	p.mu.Lock()
+	ch := p.idle[language]
+	p.mu.Unlock()
+	if ch == nil {
+		cli.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true})
+		return
+	}
+	select {
+	case ch <- containerID:
+	default:
+		cli.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true})
+	}
+}
+
+// createWarmSandbox starts an idle "sleep infinity" container for rt that
+// sits ready for ContainerExec.
+func createWarmSandbox(ctx context.Context, cli *client.Client, rt Runtime) (string, error) {
+	resp, err := cli.ContainerCreate(ctx, &container.Config{
+		Image:           rt.Image(),
+		Cmd:             []string{"sleep", "infinity"},
+		NetworkDisabled: true,
+	}, &container.HostConfig{
+		Resources: container.Resources{Memory: rt.MemoryLimit()},
+	}, nil, nil, "")
+	if err != nil {
+		return "", err
+	}
+	if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// initSandboxPools pre-creates poolSizePerLanguage idle sandboxes for every
+// configured language. Called once at startup.
+func initSandboxPools(ctx context.Context, cli *client.Client) {
+	for language, size := range poolSizePerLanguage {
+		rt, ok := runtimeRegistry[language]
+		if !ok {
+			continue
+		}
+		if rt.Compiled() {
+			fmt.Printf("⚠️  Skipping warm pool for %s: its Compile step needs a build container ContainerExec can't provide\n", language)
+			continue
+		}
+		pool.mu.Lock()
+		pool.idle[language] = make(chan string, size)
+		pool.mu.Unlock()
+
+		warmed := 0
+		for i := 0; i < size; i++ {
+			id, err := createWarmSandbox(ctx, cli, rt)
+			if err != nil {
+				fmt.Printf("⚠️  Failed to warm %s sandbox: %v\n", language, err)
+				continue
+			}
+			pool.idle[language] <- id
+			warmed++
+		}
+		fmt.Printf("🔥 Warmed %d/%d %s sandboxes\n", warmed, size, language)
+	}
+}
+
+// RedisPoolConfig configures the namespaced, durable work queue backing the
+// worker pool.
+type RedisPoolConfig struct {
+	URL               string
+	Namespace         string
+	IdleTimeoutSecond int
+}
+
+var redisPoolConfig = RedisPoolConfig{
+	URL:               "localhost:6379",
+	Namespace:         "orbit",
+	IdleTimeoutSecond: 30,
+}
+
+const (
+	maxJobAttempts = 5
+	heartbeatTTL   = 15 * time.Second
+	heartbeatEvery = 5 * time.Second
+	reaperInterval = 10 * time.Second
+)
+
+func queueKey() string             { return redisPoolConfig.Namespace + ":job_queue" }
+func inFlightKey(w string) string  { return redisPoolConfig.Namespace + ":in_flight:" + w }
+func deadLetterKey() string        { return redisPoolConfig.Namespace + ":dead_letter" }
+func heartbeatKey(w string) string { return redisPoolConfig.Namespace + ":heartbeat:" + w }
+func scheduleKey(id string) string { return redisPoolConfig.Namespace + ":schedule:" + id }
+func scheduleIndexKey() string     { return redisPoolConfig.Namespace + ":schedules" }
+func delayedRetryKey() string      { return redisPoolConfig.Namespace + ":delayed_retries" }
+
+// Schedule is a recurring (cron) or one-shot (RunAt) job submission that the
+// scheduler goroutine materializes into a Job and pushes onto job_queue
+// each time it fires.
+type Schedule struct {
+	ID             string     `json:"id"`
+	Cron           string     `json:"cron,omitempty"`
+	RunAt          string     `json:"run_at,omitempty"`
+	Language       string     `json:"language"`
+	Code           string     `json:"code"`
+	ExpectedOutput string     `json:"expected_output"`
+	TestCases      []TestCase `json:"test_cases,omitempty"`
+	NextFire       int64      `json:"next_fire"`
+	CreatedAt      int64      `json:"created_at"`
+}
+
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// nextFireTime computes when a Schedule should next run: once for a
+// RFC3339 RunAt, or repeatedly per its cron expression.
+func nextFireTime(s Schedule, after time.Time) (time.Time, error) {
+	if s.Cron != "" {
+		sched, err := cronParser.Parse(s.Cron)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid cron expression: %v", err)
+		}
+		return sched.Next(after), nil
+	}
+	t, err := time.Parse(time.RFC3339, s.RunAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid run_at timestamp: %v", err)
+	}
+	return t, nil
+}
+
+func saveSchedule(s Schedule) {
+	data, _ := json.Marshal(s)
+	rdb.Set(ctx, scheduleKey(s.ID), data, 0)
+	rdb.ZAdd(ctx, scheduleIndexKey(), redis.Z{Score: float64(s.NextFire), Member: s.ID})
+}
+
+func deleteSchedule(id string) {
+	rdb.Del(ctx, scheduleKey(id))
+	rdb.ZRem(ctx, scheduleIndexKey(), id)
+}
+
+// scheduler wakes on the earliest pending deadline in scheduleIndexKey,
+// materializes the due Schedule(s) into Jobs on job_queue, and recomputes
+// each one's next fire time (cron schedules reschedule themselves;
+// one-shot schedules are removed).
+func scheduler() {
+	for {
+		due, err := rdb.ZRangeByScoreWithScores(ctx, scheduleIndexKey(), &redis.ZRangeBy{
+			Min: "-inf", Max: fmt.Sprintf("%d", time.Now().Unix()), Count: 1,
+		}).Result()
+		if err != nil || len(due) == 0 {
+			next, err := rdb.ZRangeWithScores(ctx, scheduleIndexKey(), 0, 0).Result()
+			wait := 5 * time.Second
+			if err == nil && len(next) > 0 {
+				if d := time.Until(time.Unix(int64(next[0].Score), 0)); d > 0 && d < wait {
+					wait = d
+				}
+			}
+			time.Sleep(wait)
+			continue
+		}
+
+		id := due[0].Member.(string)
+		val, err := rdb.Get(ctx, scheduleKey(id)).Result()
+		if err != nil {
+			rdb.ZRem(ctx, scheduleIndexKey(), id)
+			continue
+		}
+		var s Schedule
+		json.Unmarshal([]byte(val), &s)
+
+		jobID := fmt.Sprintf("%d", time.Now().UnixNano())
+		job := Job{
+			ID:             jobID,
+			Language:       s.Language,
+			Code:           s.Code,
+			ExpectedOutput: s.ExpectedOutput,
+			TestCases:      s.TestCases,
+			Status:         "pending",
+			CreatedAt:      time.Now().Unix(),
+		}
+		jobJSON, _ := json.Marshal(job)
+		rdb.Set(ctx, "job:"+jobID, jobJSON, 1*time.Hour)
+		rdb.LPush(ctx, queueKey(), jobID)
+		fmt.Printf("⏰ Schedule %s fired -> job %s\n", s.ID, jobID)
+
+		if s.Cron == "" {
+			deleteSchedule(s.ID)
+			continue
+		}
+		next, err := nextFireTime(s, time.Now())
+		if err != nil {
+			deleteSchedule(s.ID)
+			continue
+		}
+		s.NextFire = next.Unix()
+		saveSchedule(s)
+	}
 }
 
 var ctx = context.Background()
 var rdb *redis.Client
 
+// runningContainers maps a job ID to the Docker container ID currently
+// executing it, so the log-streaming endpoint can attach without plumbing
+// the container ID through Redis.
+var runningContainers = struct {
+	sync.RWMutex
+	m map[string]string
+}{m: make(map[string]string)}
+
+func setRunningContainer(jobID, containerID string) {
+	runningContainers.Lock()
+	runningContainers.m[jobID] = containerID
+	runningContainers.Unlock()
+}
+
+func clearRunningContainer(jobID string) {
+	runningContainers.Lock()
+	delete(runningContainers.m, jobID)
+	runningContainers.Unlock()
+}
+
+// HasStarted reports whether jobID's container has been started and is
+// still running, returning its container ID while it is.
+func HasStarted(jobID string) (string, bool) {
+	runningContainers.RLock()
+	defer runningContainers.RUnlock()
+	containerID, ok := runningContainers.m[jobID]
+	return containerID, ok
+}
+
+// execStream fans a pooled job's exec output out to whichever
+// /jobs/:id/logs websocket subscribes to it, since a pooled job runs inside
+// a ContainerExec session that ContainerLogs (and so runningContainers)
+// can't see.
+type execStream struct {
+	mu   sync.Mutex
+	subs []chan []byte
+}
+
+func (s *execStream) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chunk := append([]byte(nil), p...)
+	for _, sub := range s.subs {
+		select {
+		case sub <- chunk:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+func (s *execStream) subscribe() chan []byte {
+	ch := make(chan []byte, 16)
+	s.mu.Lock()
+	s.subs = append(s.subs, ch)
+	s.mu.Unlock()
+	return ch
+}
+
+// Close signals every current subscriber that the job's output is done.
+func (s *execStream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sub := range s.subs {
+		close(sub)
+	}
+	s.subs = nil
+}
+
+// execStreams maps a job ID to its live execStream for as long as runInPool
+// is running that job, mirroring runningContainers' role for the cold path.
+var execStreams = struct {
+	sync.RWMutex
+	m map[string]*execStream
+}{m: make(map[string]*execStream)}
+
+func registerExecStream(jobID string) *execStream {
+	s := &execStream{}
+	execStreams.Lock()
+	execStreams.m[jobID] = s
+	execStreams.Unlock()
+	return s
+}
+
+func clearExecStream(jobID string) {
+	execStreams.Lock()
+	delete(execStreams.m, jobID)
+	execStreams.Unlock()
+}
+
+// getExecStream returns jobID's live execStream, if a pooled run is
+// currently producing output for it.
+func getExecStream(jobID string) (*execStream, bool) {
+	execStreams.RLock()
+	defer execStreams.RUnlock()
+	s, ok := execStreams.m[jobID]
+	return s, ok
+}
+
+var wsUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// wsWriter adapts a websocket connection to io.Writer so stdcopy.StdCopy can
+// demultiplex a Docker log stream straight into it one frame at a time.
+type wsWriter struct{ conn *websocket.Conn }
+
+func (w wsWriter) Write(p []byte) (int, error) {
+	if err := w.conn.WriteMessage(websocket.TextMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
 func main() {
 	fmt.Println("🔌 Connecting to Redis...")
 	rdb = redis.NewClient(&redis.Options{
-		Addr: "localhost:6379",
+		Addr: redisPoolConfig.URL,
 	})
 	if _, err := rdb.Ping(ctx).Result(); err != nil {
 		fmt.Println("⚠️  Redis not found. Ensure docker-compose is up.")
@@ -68,40 +609,192 @@ func main() {
 	fmt.Println("✅ Connected to Redis")
 
 	concurrency := 5
-	fmt.Printf("👷 Starting %d Workers...\n", concurrency)
+	fmt.Printf("👷 Starting %d Workers (namespace=%s)...\n", concurrency, redisPoolConfig.Namespace)
+	workerIDs := make([]string, 0, concurrency)
 	for i := 1; i <= concurrency; i++ {
+		workerIDs = append(workerIDs, fmt.Sprintf("%d", i))
 		go startWorker(i)
 	}
+	go reaper(workerIDs)
+	go reportQueueMetrics(workerIDs)
+	go scheduler()
+	go retryScheduler()
+
+	if poolCli, err := client.NewClientWithOpts(client.FromEnv, client.WithVersion("1.45")); err == nil {
+		initSandboxPools(ctx, poolCli)
+	} else {
+		fmt.Printf("⚠️  Could not warm sandbox pools: %v\n", err)
+	}
 
 	r := gin.Default()
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	r.POST("/submit", func(c *gin.Context) {
 		var req struct {
-			Code           string `json:"code"`
-			ExpectedOutput string `json:"expected_output"`
+			Language       string     `json:"language"`
+			Code           string     `json:"code"`
+			ExpectedOutput string     `json:"expected_output"`
+			TestCases      []TestCase `json:"test_cases"`
 		}
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
 			return
 		}
 
+		language := strings.ToLower(strings.TrimSpace(req.Language))
+		if language == "" {
+			language = "python"
+		}
+		if _, ok := runtimeRegistry[language]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported language: " + language})
+			return
+		}
+
 		jobID := fmt.Sprintf("%d", time.Now().UnixNano())
 		job := Job{
 			ID:             jobID,
+			Language:       language,
 			Code:           req.Code,
 			ExpectedOutput: req.ExpectedOutput,
+			TestCases:      req.TestCases,
 			Status:         "pending",
 			CreatedAt:      time.Now().Unix(),
 		}
 
 		jobJSON, _ := json.Marshal(job)
 		rdb.Set(ctx, "job:"+jobID, jobJSON, 1*time.Hour)
-		rdb.LPush(ctx, "job_queue", jobID)
+		rdb.LPush(ctx, queueKey(), jobID)
 
 		c.JSON(http.StatusAccepted, gin.H{"job_id": jobID, "message": "Job queued"})
 	})
 
+	r.GET("/jobs/:id/logs", func(c *gin.Context) {
+		jobID := c.Param("id")
+
+		var containerID string
+		var stream *execStream
+		for i := 0; i < 100; i++ {
+			if id, ok := HasStarted(jobID); ok {
+				containerID = id
+				break
+			}
+			if s, ok := getExecStream(jobID); ok {
+				stream = s
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		if containerID == "" && stream == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job has not started"})
+			return
+		}
+
+		conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// A pooled job (the default python path) has no PID-1 container to
+		// follow with ContainerLogs, so relay its execStream instead: the
+		// same output runInPool is copying into its result buffers.
+		if stream != nil {
+			for chunk := range stream.subscribe() {
+				if err := conn.WriteMessage(websocket.TextMessage, chunk); err != nil {
+					return
+				}
+			}
+			return
+		}
+
+		cli, err := client.NewClientWithOpts(client.FromEnv, client.WithVersion("1.45"))
+		if err != nil {
+			conn.WriteMessage(websocket.TextMessage, []byte("client error: "+err.Error()))
+			return
+		}
+
+		out, err := cli.ContainerLogs(ctx, containerID, types.ContainerLogsOptions{
+			ShowStdout: true, ShowStderr: true, Follow: true,
+		})
+		if err != nil {
+			conn.WriteMessage(websocket.TextMessage, []byte("logs error: "+err.Error()))
+			return
+		}
+		defer out.Close()
+
+		stdcopy.StdCopy(wsWriter{conn}, wsWriter{conn}, out)
+	})
+
+	r.POST("/schedule", func(c *gin.Context) {
+		var req struct {
+			Cron           string     `json:"cron"`
+			RunAt          string     `json:"run_at"`
+			Language       string     `json:"language"`
+			Code           string     `json:"code"`
+			ExpectedOutput string     `json:"expected_output"`
+			TestCases      []TestCase `json:"test_cases"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+			return
+		}
+		if req.Cron == "" && req.RunAt == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Either cron or run_at is required"})
+			return
+		}
+
+		language := strings.ToLower(strings.TrimSpace(req.Language))
+		if language == "" {
+			language = "python"
+		}
+		if _, ok := runtimeRegistry[language]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported language: " + language})
+			return
+		}
+
+		s := Schedule{
+			ID:             fmt.Sprintf("sched_%d", time.Now().UnixNano()),
+			Cron:           req.Cron,
+			RunAt:          req.RunAt,
+			Language:       language,
+			Code:           req.Code,
+			ExpectedOutput: req.ExpectedOutput,
+			TestCases:      req.TestCases,
+			CreatedAt:      time.Now().Unix(),
+		}
+
+		next, err := nextFireTime(s, time.Now())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		s.NextFire = next.Unix()
+		saveSchedule(s)
+
+		c.JSON(http.StatusAccepted, gin.H{"schedule_id": s.ID, "next_fire": s.NextFire})
+	})
+
+	r.GET("/schedule/:id", func(c *gin.Context) {
+		val, err := rdb.Get(ctx, scheduleKey(c.Param("id"))).Result()
+		if err == redis.Nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+			return
+		}
+		var s Schedule
+		json.Unmarshal([]byte(val), &s)
+		c.JSON(http.StatusOK, s)
+	})
+
+	r.DELETE("/schedule/:id", func(c *gin.Context) {
+		id := c.Param("id")
+		if _, err := rdb.Get(ctx, scheduleKey(id)).Result(); err == redis.Nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+			return
+		}
+		deleteSchedule(id)
+		c.JSON(http.StatusOK, gin.H{"message": "Schedule removed"})
+	})
+
 	r.GET("/status/:id", func(c *gin.Context) {
 		jobID := c.Param("id")
 		val, err := rdb.Get(ctx, "job:"+jobID).Result()
@@ -118,55 +811,238 @@ func main() {
 	r.Run(":8080")
 }
 
+// startWorker runs a reliable-delivery loop: BRPOPLPUSH claims a job into
+// this worker's namespaced in-flight list so the reaper can requeue it if
+// the worker dies mid-job, instead of the old fire-and-forget BLPop.
 func startWorker(workerID int) {
+	wid := fmt.Sprintf("%d", workerID)
 	fmt.Printf("👷 Worker %d ready.\n", workerID)
+	go heartbeatLoop(wid)
+
 	for {
-		result, err := rdb.BLPop(ctx, 0*time.Second, "job_queue").Result()
+		idleTimeout := time.Duration(redisPoolConfig.IdleTimeoutSecond) * time.Second
+		jobID, err := rdb.BRPopLPush(ctx, queueKey(), inFlightKey(wid), idleTimeout).Result()
+		if err == redis.Nil {
+			continue
+		}
 		if err != nil {
+			time.Sleep(time.Second)
 			continue
 		}
 
-		jobID := result[1]
-		val, _ := rdb.Get(ctx, "job:"+jobID).Result()
+		val, err := rdb.Get(ctx, "job:"+jobID).Result()
+		if err != nil {
+			rdb.LRem(ctx, inFlightKey(wid), 1, jobID)
+			continue
+		}
 		var job Job
 		json.Unmarshal([]byte(val), &job)
 
 		job.Status = "processing"
 		updateJob(job)
 
-		output, err := executePythonCode(job.Code)
-		job.ActualOutput = output
-
-		isRuntimeError := false
-		if err != nil {
-			isRuntimeError = true 
-			job.ActualOutput = err.Error()
-		} else if strings.Contains(output, "Traceback (most recent call last)") || strings.Contains(output, "Error:") {
-			isRuntimeError = true 
+		var gradeErr error
+		if len(job.TestCases) > 0 {
+			gradeErr = gradeJob(workerID, &job)
+		} else {
+			gradeErr = gradeLegacyJob(workerID, &job)
 		}
 
-		if isRuntimeError {
-			job.Status = "failed"
-			job.Verdict = "Runtime Error"
-			
-			fmt.Printf("🤖 [Worker %d] Runtime Error detected. Calling Nexus...\n", workerID)
-			job.AiDiagnosis = callNexusAI(job.Code, job.ActualOutput)
-			aiCalls.Inc()
-		} else {
-			job.Status = "completed"
-			
-			if strings.TrimSpace(job.ActualOutput) == strings.TrimSpace(job.ExpectedOutput) {
-				job.Verdict = "Passed"
-			} else {
-				job.Verdict = "Failed"
-			}
+		if gradeErr != nil {
+			rdb.LRem(ctx, inFlightKey(wid), 1, jobID)
+			requeueOrDeadLetter(&job, gradeErr)
+			continue
 		}
 
 		updateJob(job)
 		jobsProcessed.Inc()
+		rdb.LRem(ctx, inFlightKey(wid), 1, jobID)
 		fmt.Printf("✅ [Worker %d] Job %s -> Verdict: %s\n", workerID, jobID, job.Verdict)
 	}
 }
+
+// gradeLegacyJob runs the pre-test_cases single Code/ExpectedOutput flow,
+// kept for clients that haven't moved to the test_cases payload yet.
+func gradeLegacyJob(workerID int, job *Job) error {
+	output, err := executeCode(job.ID, job.Language, job.Code)
+	job.ActualOutput = output
+	var compileErr *CompileError
+	if errors.As(err, &compileErr) {
+		job.Status = "failed"
+		job.Verdict = "Compilation Error"
+		job.ActualOutput = compileErr.Output
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	isRuntimeError := strings.Contains(output, "Traceback (most recent call last)") || strings.Contains(output, "Error:")
+	if isRuntimeError {
+		job.Status = "failed"
+		job.Verdict = "Runtime Error"
+
+		fmt.Printf("🤖 [Worker %d] Runtime Error detected. Calling Nexus...\n", workerID)
+		job.AiDiagnosis = callNexusAI(job.Code, job.ActualOutput)
+		aiCalls.Inc()
+	} else {
+		job.Status = "completed"
+		if strings.TrimSpace(job.ActualOutput) == strings.TrimSpace(job.ExpectedOutput) {
+			job.Verdict = "Passed"
+		} else {
+			job.Verdict = "Failed"
+		}
+	}
+	return nil
+}
+
+// gradeJob runs every TestCase on job independently, recording a CaseResult
+// each, and rolls the per-case verdicts up into an aggregate Job.Verdict.
+func gradeJob(workerID int, job *Job) error {
+	job.TestResults = make([]CaseResult, 0, len(job.TestCases))
+	allPassed := true
+	anyRuntimeError := false
+
+	for i, tc := range job.TestCases {
+		result, err := runTestCase(job.ID, job.Language, job.Code, tc)
+		var compileErr *CompileError
+		if errors.As(err, &compileErr) {
+			job.Status = "failed"
+			job.Verdict = "Compilation Error"
+			job.TestResults = nil
+			job.ActualOutput = compileErr.Output
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		job.TestResults = append(job.TestResults, result)
+		if result.Verdict != "Passed" {
+			allPassed = false
+		}
+		if result.Verdict == "Runtime Error" {
+			anyRuntimeError = true
+		}
+		fmt.Printf("📋 [Worker %d] Job %s case %d/%d -> %s\n", workerID, job.ID, i+1, len(job.TestCases), result.Verdict)
+	}
+
+	job.Status = "completed"
+	switch {
+	case allPassed:
+		job.Verdict = "Passed"
+	case anyRuntimeError:
+		job.Verdict = "Runtime Error"
+		fmt.Printf("🤖 [Worker %d] Runtime Error detected. Calling Nexus...\n", workerID)
+		job.AiDiagnosis = callNexusAI(job.Code, job.TestResults[len(job.TestResults)-1].Stderr)
+		aiCalls.Inc()
+	default:
+		job.Verdict = "Failed"
+	}
+	return nil
+}
+
+// requeueOrDeadLetter handles an infrastructure-level execution failure
+// (Docker errors, not a graded verdict): retry with exponential backoff up
+// to maxJobAttempts, then give up into the dead-letter queue.
+func requeueOrDeadLetter(job *Job, execErr error) {
+	job.Attempts++
+	if job.Attempts >= maxJobAttempts {
+		job.Status = "dead_letter"
+		job.Verdict = "Runtime Error"
+		job.ActualOutput = execErr.Error()
+		updateJob(*job)
+
+		jobJSON, _ := json.Marshal(job)
+		rdb.LPush(ctx, deadLetterKey(), jobJSON)
+		fmt.Printf("☠️  Job %s moved to dead-letter after %d attempts: %v\n", job.ID, job.Attempts, execErr)
+		return
+	}
+
+	job.Status = "pending"
+	updateJob(*job)
+
+	backoff := time.Duration(1<<uint(job.Attempts)) * time.Second
+	fmt.Printf("🔁 Job %s execution failed (attempt %d/%d), retrying in %s: %v\n",
+		job.ID, job.Attempts, maxJobAttempts, backoff, execErr)
+	rdb.ZAdd(ctx, delayedRetryKey(), redis.Z{
+		Score: float64(time.Now().Add(backoff).Unix()), Member: job.ID,
+	})
+}
+
+// retryScheduler wakes on the earliest due member of delayedRetryKey and
+// pushes it back onto job_queue, mirroring scheduler()'s poll loop. The
+// backoff is tracked in Redis rather than an in-process timer so a retry
+// survives a worker restart instead of being silently dropped.
+func retryScheduler() {
+	for {
+		due, err := rdb.ZRangeByScoreWithScores(ctx, delayedRetryKey(), &redis.ZRangeBy{
+			Min: "-inf", Max: fmt.Sprintf("%d", time.Now().Unix()), Count: 1,
+		}).Result()
+		if err != nil || len(due) == 0 {
+			next, err := rdb.ZRangeWithScores(ctx, delayedRetryKey(), 0, 0).Result()
+			wait := 5 * time.Second
+			if err == nil && len(next) > 0 {
+				if d := time.Until(time.Unix(int64(next[0].Score), 0)); d > 0 && d < wait {
+					wait = d
+				}
+			}
+			time.Sleep(wait)
+			continue
+		}
+
+		jobID := due[0].Member.(string)
+		rdb.ZRem(ctx, delayedRetryKey(), jobID)
+		rdb.LPush(ctx, queueKey(), jobID)
+		fmt.Printf("🔁 Delayed retry for job %s pushed back onto job_queue\n", jobID)
+	}
+}
+
+// heartbeatLoop keeps a worker's heartbeat key alive so the reaper can tell
+// a slow worker from a dead one.
+func heartbeatLoop(workerID string) {
+	for {
+		rdb.Set(ctx, heartbeatKey(workerID), time.Now().Unix(), heartbeatTTL)
+		time.Sleep(heartbeatEvery)
+	}
+}
+
+// reaper requeues jobs stranded in a worker's in-flight list once that
+// worker's heartbeat has expired, so a crashed worker doesn't silently lose
+// the jobs it had claimed.
+func reaper(workerIDs []string) {
+	for {
+		time.Sleep(reaperInterval)
+		for _, wid := range workerIDs {
+			if rdb.Exists(ctx, heartbeatKey(wid)).Val() > 0 {
+				continue
+			}
+			for {
+				jobID, err := rdb.RPopLPush(ctx, inFlightKey(wid), queueKey()).Result()
+				if err != nil {
+					break
+				}
+				fmt.Printf("♻️  Reaper requeued job %s from dead worker %s\n", jobID, wid)
+			}
+		}
+	}
+}
+
+// reportQueueMetrics keeps the queue-depth / in-flight / dead-letter gauges
+// fresh for Prometheus scrapes.
+func reportQueueMetrics(workerIDs []string) {
+	for {
+		queueDepth.Set(float64(rdb.LLen(ctx, queueKey()).Val()))
+		deadLetterJobs.Set(float64(rdb.LLen(ctx, deadLetterKey()).Val()))
+
+		var inFlight int64
+		for _, wid := range workerIDs {
+			inFlight += rdb.LLen(ctx, inFlightKey(wid)).Val()
+		}
+		inFlightJobs.Set(float64(inFlight))
+
+		time.Sleep(5 * time.Second)
+	}
+}
 func updateJob(job Job) {
 	data, _ := json.Marshal(job)
 	rdb.Set(ctx, "job:"+job.ID, data, 1*time.Hour)
@@ -187,35 +1063,123 @@ func callNexusAI(code, errorMsg string) string {
 	body, _ := io.ReadAll(resp.Body)
 	var result map[string]string
 	json.Unmarshal(body, &result)
-	
+
 	return result["analysis"]
 }
 
-func executePythonCode(pythonCode string) (string, error) {
+// buildInContainer runs buildCmd in a throwaway container of image, with
+// srcDir bind-mounted at /app, producing the compiled artifact in /app. This
+// is the "build container" half of the two-phase compile+run flow; the
+// caller is responsible for running the resulting binary in its own
+// container afterwards. memoryLimit caps the build container the same way
+// the run container is capped, so a submission can't OOM the host during
+// compilation (e.g. a template-heavy C++ file) any more than it could
+// during execution.
+func buildInContainer(ctx context.Context, cli *client.Client, image, srcDir string, buildCmd []string, memoryLimit int64) (string, error) {
+	resp, err := cli.ContainerCreate(ctx, &container.Config{
+		Image:           image,
+		Cmd:             buildCmd,
+		WorkingDir:      "/app",
+		NetworkDisabled: true,
+	}, &container.HostConfig{
+		Mounts: []mount.Mount{
+			{Type: mount.TypeBind, Source: srcDir, Target: "/app"},
+		},
+		Resources: container.Resources{Memory: memoryLimit},
+	}, nil, nil, "")
+	if err != nil {
+		return "", fmt.Errorf("build create error: %v", err)
+	}
+	defer cli.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+
+	if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return "", fmt.Errorf("build start error: %v", err)
+	}
+
+	statusCh, errCh := cli.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
+	var exitCode int64
+	var waitErr error
+	select {
+	case status := <-statusCh:
+		exitCode = status.StatusCode
+		if status.Error != nil {
+			waitErr = fmt.Errorf("%s", status.Error.Message)
+		}
+	case waitErr = <-errCh:
+	case <-time.After(30 * time.Second):
+		cli.ContainerKill(ctx, resp.ID, "SIGKILL")
+		return "", fmt.Errorf("compile timed out")
+	}
+
+	out, _ := cli.ContainerLogs(ctx, resp.ID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
+	buf := new(bytes.Buffer)
+	stdcopy.StdCopy(buf, buf, out)
+
+	if waitErr != nil {
+		return "", fmt.Errorf("build wait error: %v", waitErr)
+	}
+	if exitCode != 0 {
+		return "", &CompileError{Output: buf.String()}
+	}
+
+	return filepath.Join("/app", "bin"), nil
+}
+
+// executeCode runs code for the given language's registered Runtime,
+// compiling it first when the runtime requires it. It is the generalized
+// successor to the Python-only executePythonCode.
+func executeCode(jobID, language, code string) (string, error) {
+	start := time.Now()
+	defer func() { execLatency.Observe(time.Since(start).Seconds()) }()
+
+	if pool.configured(language) {
+		output, err := runInPool(jobID, language, code)
+		if err == nil {
+			return output, nil
+		}
+		fmt.Printf("⚠️  Warm pool run failed for job %s, falling back to cold start: %v\n", jobID, err)
+	}
+	return executeColdCode(jobID, language, code)
+}
+
+// executeColdCode is the original ContainerCreate+Start+Remove path, used
+// for languages without a warm pool and as the fallback when a pooled
+// sandbox run fails.
+func executeColdCode(jobID, language, code string) (string, error) {
+	rt, ok := runtimeRegistry[language]
+	if !ok {
+		return "", fmt.Errorf("unsupported language: %s", language)
+	}
+
 	ctx := context.Background()
 	cwd, _ := os.Getwd()
-	tempDir := filepath.Join(cwd, "temp-jobs")
+	tempDir := filepath.Join(cwd, "temp-jobs", fmt.Sprintf("job_%d", time.Now().UnixNano()))
 	os.MkdirAll(tempDir, 0755)
-	
-	fileName := fmt.Sprintf("job_%d.py", time.Now().UnixNano())
+	defer os.RemoveAll(tempDir)
+
+	fileName := "submission" + rt.Ext()
 	filePath := filepath.Join(tempDir, fileName)
-	os.WriteFile(filePath, []byte(pythonCode), 0644)
-	defer os.Remove(filePath)
+	os.WriteFile(filePath, []byte(code), 0644)
 
 	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithVersion("1.45"))
 	if err != nil {
 		return "", fmt.Errorf("client error: %v", err)
 	}
 
+	runPath, err := rt.Compile(ctx, cli, tempDir, fileName)
+	if err != nil {
+		return "", fmt.Errorf("compile error: %w", err)
+	}
+
 	resp, err := cli.ContainerCreate(ctx, &container.Config{
-		Image:           "python:alpine",
-		Cmd:             []string{"python", "-u", "/app/" + fileName}, 
+		Image:           rt.Image(),
+		Cmd:             rt.Cmd(runPath),
 		NetworkDisabled: true,
 	}, &container.HostConfig{
 		Mounts: []mount.Mount{
-			{Type: mount.TypeBind, Source: filePath, Target: "/app/" + fileName, ReadOnly: true},
+			{Type: mount.TypeBind, Source: tempDir, Target: "/app"},
 		},
-		Resources: container.Resources{Memory: 128 * 1024 * 1024},
+		Resources: container.Resources{Memory: rt.MemoryLimit()},
 	}, nil, nil, "")
 	if err != nil {
 		return "", fmt.Errorf("create error: %v", err)
@@ -224,6 +1188,8 @@ func executePythonCode(pythonCode string) (string, error) {
 	if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
 		return "", fmt.Errorf("start error: %v", err)
 	}
+	setRunningContainer(jobID, resp.ID)
+	defer clearRunningContainer(jobID)
 
 	statusCh, errCh := cli.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
 	var timeoutWarning string
@@ -237,17 +1203,293 @@ func executePythonCode(pythonCode string) (string, error) {
 
 	out, _ := cli.ContainerLogs(ctx, resp.ID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
 	stdOutBuf := new(bytes.Buffer)
-	stdErrBuf := new(bytes.Buffer) 
-	
+	stdErrBuf := new(bytes.Buffer)
+
 	stdcopy.StdCopy(stdOutBuf, stdErrBuf, out)
 
 	finalOutput := stdOutBuf.String()
 	if stdErrBuf.Len() > 0 {
-		finalOutput += "\n" + stdErrBuf.String() 
+		finalOutput += "\n" + stdErrBuf.String()
 	}
 	finalOutput += timeoutWarning
-	
+
 	cli.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{})
 
 	return finalOutput, nil
-}
\ No newline at end of file
+}
+
+// tarSingleFile wraps content in a tar stream containing one file, the
+// format CopyToContainer requires.
+func tarSingleFile(name string, content []byte) (*bytes.Buffer, error) {
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(content); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// runInPool executes code against a warm sandbox from the pool via
+// ContainerExec instead of creating a fresh container, avoiding the
+// hundreds of milliseconds of Docker overhead ContainerCreate+Start pays
+// per job.
+func runInPool(jobID, language, code string) (string, error) {
+	rt := runtimeRegistry[language]
+
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithVersion("1.45"))
+	if err != nil {
+		return "", fmt.Errorf("client error: %v", err)
+	}
+
+	waitStart := time.Now()
+	containerID, err := pool.acquire(ctx, cli, language, rt)
+	poolWaitSeconds.Observe(time.Since(waitStart).Seconds())
+	if err != nil {
+		return "", err
+	}
+
+	fileName := "submission" + rt.Ext()
+	tarBuf, err := tarSingleFile(fileName, []byte(code))
+	if err != nil {
+		pool.release(ctx, cli, language, containerID)
+		return "", fmt.Errorf("tar error: %v", err)
+	}
+	if err := cli.CopyToContainer(ctx, containerID, "/app", tarBuf, types.CopyToContainerOptions{}); err != nil {
+		pool.release(ctx, cli, language, containerID)
+		return "", fmt.Errorf("copy error: %v", err)
+	}
+
+	// Per-exec memory cgroup adjustment: the sandbox is reused across many
+	// jobs, so re-apply the runtime's limit before every run rather than
+	// trusting whatever the last job left behind.
+	cli.ContainerUpdate(ctx, containerID, container.UpdateConfig{
+		Resources: container.Resources{Memory: rt.MemoryLimit()},
+	})
+
+	execResp, err := cli.ContainerExecCreate(ctx, containerID, types.ExecConfig{
+		Cmd:          rt.Cmd(filepath.Join("/app", fileName)),
+		WorkingDir:   "/app",
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		pool.release(ctx, cli, language, containerID)
+		return "", fmt.Errorf("exec create error: %v", err)
+	}
+
+	// Not registered via setRunningContainer: the logs endpoint's
+	// ContainerLogs call only sees a container's own PID-1 output, never a
+	// ContainerExec session's. It attaches to this job's execStream
+	// instead, which is fed directly from the exec output below.
+	attach, err := cli.ContainerExecAttach(ctx, execResp.ID, types.ExecStartCheck{})
+	if err != nil {
+		pool.release(ctx, cli, language, containerID)
+		return "", fmt.Errorf("exec attach error: %v", err)
+	}
+	defer attach.Close()
+
+	stream := registerExecStream(jobID)
+	defer clearExecStream(jobID)
+
+	stdOutBuf := new(bytes.Buffer)
+	stdErrBuf := new(bytes.Buffer)
+	copyDone := make(chan struct{})
+	go func() {
+		stdcopy.StdCopy(io.MultiWriter(stdOutBuf, stream), io.MultiWriter(stdErrBuf, stream), attach.Reader)
+		close(copyDone)
+	}()
+
+	select {
+	case <-copyDone:
+		stream.Close()
+	case <-time.After(defaultTimeLimitMs * time.Millisecond):
+		// An exec session can't be waited on or killed directly the way a
+		// container can, so a wedged submission (e.g. an infinite loop)
+		// would otherwise pin this worker goroutine forever. Kill the
+		// whole sandbox instead of returning it to the pool: it's the
+		// only way to stop the exec, and a container that just ran an
+		// infinite loop isn't safe to hand to the next job anyway.
+		stream.Close()
+		cli.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true})
+		return "", fmt.Errorf("execution timed out")
+	}
+
+	// Clean up /app before returning the sandbox to the idle pool so the
+	// next job doesn't see this job's submission.
+	cleanup, err := cli.ContainerExecCreate(ctx, containerID, types.ExecConfig{
+		Cmd: []string{"sh", "-c", "rm -rf /app/* /app/.[!.]*"},
+	})
+	if err == nil {
+		cli.ContainerExecStart(ctx, cleanup.ID, types.ExecStartCheck{})
+	}
+	pool.release(ctx, cli, language, containerID)
+
+	finalOutput := stdOutBuf.String()
+	if stdErrBuf.Len() > 0 {
+		finalOutput += "\n" + stdErrBuf.String()
+	}
+	return finalOutput, nil
+}
+
+// isOOMKilled reports whether the container was killed by the kernel OOM
+// killer, distinguishing an MLE verdict from a plain TLE/crash.
+func isOOMKilled(ctx context.Context, cli *client.Client, containerID string) bool {
+	info, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return false
+	}
+	return info.State.OOMKilled
+}
+
+// runTestCase grades one TestCase: it pipes Stdin into the container over
+// ContainerAttach, enforces the case's time and memory limits, and samples
+// ContainerStats to record peak RSS alongside wall-clock time.
+func runTestCase(jobID, language, code string, tc TestCase) (CaseResult, error) {
+	rt, ok := runtimeRegistry[language]
+	if !ok {
+		return CaseResult{}, fmt.Errorf("unsupported language: %s", language)
+	}
+
+	timeLimit := time.Duration(tc.TimeLimitMs) * time.Millisecond
+	if tc.TimeLimitMs <= 0 {
+		timeLimit = defaultTimeLimitMs * time.Millisecond
+	}
+	memoryLimit := int64(tc.MemoryMB) * 1024 * 1024
+	if memoryLimit <= 0 {
+		memoryLimit = rt.MemoryLimit()
+	}
+
+	ctx := context.Background()
+	cwd, _ := os.Getwd()
+	tempDir := filepath.Join(cwd, "temp-jobs", fmt.Sprintf("job_%d", time.Now().UnixNano()))
+	os.MkdirAll(tempDir, 0755)
+	defer os.RemoveAll(tempDir)
+
+	fileName := "submission" + rt.Ext()
+	os.WriteFile(filepath.Join(tempDir, fileName), []byte(code), 0644)
+
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithVersion("1.45"))
+	if err != nil {
+		return CaseResult{}, fmt.Errorf("client error: %v", err)
+	}
+
+	runPath, err := rt.Compile(ctx, cli, tempDir, fileName)
+	if err != nil {
+		return CaseResult{}, fmt.Errorf("compile error: %w", err)
+	}
+
+	resp, err := cli.ContainerCreate(ctx, &container.Config{
+		Image:           rt.Image(),
+		Cmd:             rt.Cmd(runPath),
+		OpenStdin:       true,
+		StdinOnce:       true,
+		AttachStdin:     true,
+		AttachStdout:    true,
+		AttachStderr:    true,
+		NetworkDisabled: true,
+	}, &container.HostConfig{
+		Mounts: []mount.Mount{
+			{Type: mount.TypeBind, Source: tempDir, Target: "/app"},
+		},
+		Resources: container.Resources{Memory: memoryLimit},
+	}, nil, nil, "")
+	if err != nil {
+		return CaseResult{}, fmt.Errorf("create error: %v", err)
+	}
+	defer cli.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+
+	attach, err := cli.ContainerAttach(ctx, resp.ID, types.ContainerAttachOptions{
+		Stream: true, Stdin: true, Stdout: true, Stderr: true,
+	})
+	if err != nil {
+		return CaseResult{}, fmt.Errorf("attach error: %v", err)
+	}
+	defer attach.Close()
+
+	if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return CaseResult{}, fmt.Errorf("start error: %v", err)
+	}
+	setRunningContainer(jobID, resp.ID)
+	defer clearRunningContainer(jobID)
+
+	io.WriteString(attach.Conn, tc.Stdin)
+	attach.CloseWrite()
+
+	stdOutBuf := new(bytes.Buffer)
+	stdErrBuf := new(bytes.Buffer)
+	copyDone := make(chan struct{})
+	go func() {
+		stdcopy.StdCopy(stdOutBuf, stdErrBuf, attach.Reader)
+		close(copyDone)
+	}()
+
+	var peakRSS int64
+	quitStats := make(chan struct{})
+	statsDone := make(chan struct{})
+	go func() {
+		defer close(statsDone)
+		for {
+			select {
+			case <-quitStats:
+				return
+			default:
+			}
+			stats, err := cli.ContainerStats(ctx, resp.ID, false)
+			if err != nil {
+				return
+			}
+			var v types.StatsJSON
+			json.NewDecoder(stats.Body).Decode(&v)
+			stats.Body.Close()
+			if int64(v.MemoryStats.MaxUsage) > peakRSS {
+				peakRSS = int64(v.MemoryStats.MaxUsage)
+			}
+			time.Sleep(200 * time.Millisecond)
+		}
+	}()
+
+	start := time.Now()
+	statusCh, errCh := cli.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
+	timedOut := false
+	select {
+	case <-statusCh:
+	case <-errCh:
+	case <-time.After(timeLimit):
+		cli.ContainerKill(ctx, resp.ID, "SIGKILL")
+		timedOut = true
+	}
+	wallTime := time.Since(start)
+	close(quitStats)
+	<-statsDone
+	<-copyDone
+
+	oomKilled := isOOMKilled(ctx, cli, resp.ID)
+
+	result := CaseResult{
+		Stdout:     stdOutBuf.String(),
+		Stderr:     stdErrBuf.String(),
+		WallTimeMs: wallTime.Milliseconds(),
+		PeakRSSKB:  peakRSS / 1024,
+	}
+
+	switch {
+	case timedOut:
+		result.Verdict = "TLE"
+	case oomKilled:
+		result.Verdict = "MLE"
+	case strings.Contains(result.Stderr, "Traceback (most recent call last)") || strings.Contains(result.Stderr, "Error:"):
+		result.Verdict = "Runtime Error"
+	case strings.TrimSpace(result.Stdout) == strings.TrimSpace(tc.ExpectedOutput):
+		result.Verdict = "Passed"
+	default:
+		result.Verdict = "Wrong Answer"
+	}
+
+	return result, nil
+}